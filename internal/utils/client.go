@@ -0,0 +1,139 @@
+package utils
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Client wraps an *http.Client backed by a tunable *http.Transport so callers can control
+// connection pooling (idle connection limits, keep-alive behaviour, TLS, proxying) and
+// per-call timeouts instead of going through http.DefaultClient, which keeps only 2 idle
+// connections per host and never times out. This matters most when restest is driving load tests against a service:
+// a shared, properly pooled client avoids exhausting ephemeral ports and sockets left in
+// TIME_WAIT under concurrent requests.
+type Client struct {
+	httpClient          *http.Client
+	passthroughEncoding bool
+}
+
+// ClientOption configures a Client constructed via NewClient.
+type ClientOption func(*clientOptions)
+
+type clientOptions struct {
+	maxIdleConns        int
+	maxIdleConnsPerHost int
+	idleConnTimeout     time.Duration
+	tlsClientConfig     *tls.Config
+	proxy               func(*http.Request) (*url.URL, error)
+	timeout             time.Duration
+	passthroughEncoding bool
+}
+
+// WithPassthroughCompression disables the default behavior of requesting gzip/deflate encoding
+// and transparently decoding it: requests are sent without an Accept-Encoding header added by
+// the Client, and response bodies are handed back exactly as received. Use this when proxying a
+// response body verbatim and the original Content-Encoding header must be preserved.
+func WithPassthroughCompression() ClientOption {
+	return func(o *clientOptions) {
+		o.passthroughEncoding = true
+	}
+}
+
+// WithMaxIdleConns sets the maximum number of idle (keep-alive) connections across all hosts.
+// Zero means no limit. Defaults to 100.
+func WithMaxIdleConns(n int) ClientOption {
+	return func(o *clientOptions) {
+		o.maxIdleConns = n
+	}
+}
+
+// WithMaxIdleConnsPerHost sets the maximum idle (keep-alive) connections kept per-host.
+// Defaults to 10 (http.DefaultTransport keeps 2); raise it when driving concurrent load
+// against a single host to avoid repeatedly paying connection setup cost.
+func WithMaxIdleConnsPerHost(n int) ClientOption {
+	return func(o *clientOptions) {
+		o.maxIdleConnsPerHost = n
+	}
+}
+
+// WithIdleConnTimeout sets how long an idle connection is kept in the pool before being closed.
+// Defaults to 90s.
+func WithIdleConnTimeout(d time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.idleConnTimeout = d
+	}
+}
+
+// WithTLSClientConfig sets the TLS configuration used for https requests, e.g. to trust a
+// custom CA or present a client certificate.
+func WithTLSClientConfig(cfg *tls.Config) ClientOption {
+	return func(o *clientOptions) {
+		o.tlsClientConfig = cfg
+	}
+}
+
+// WithProxy sets the proxy function used to select a proxy for a given request. Defaults to
+// http.ProxyFromEnvironment.
+func WithProxy(proxy func(*http.Request) (*url.URL, error)) ClientOption {
+	return func(o *clientOptions) {
+		o.proxy = proxy
+	}
+}
+
+// WithTimeout bounds the total time allowed for a single call (dial, TLS handshake, request,
+// response headers and body). Zero means no timeout. Defaults to 30s.
+func WithTimeout(d time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.timeout = d
+	}
+}
+
+// NewClient builds a Client with pooling and timeout behaviour configured via opts.
+func NewClient(opts ...ClientOption) *Client {
+	o := clientOptions{
+		maxIdleConns:        100,
+		maxIdleConnsPerHost: 10,
+		idleConnTimeout:     90 * time.Second,
+		proxy:               http.ProxyFromEnvironment,
+		timeout:             30 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return &Client{
+		httpClient: &http.Client{
+			Timeout: o.timeout,
+			Transport: &http.Transport{
+				Proxy:               o.proxy,
+				MaxIdleConns:        o.maxIdleConns,
+				MaxIdleConnsPerHost: o.maxIdleConnsPerHost,
+				IdleConnTimeout:     o.idleConnTimeout,
+				TLSClientConfig:     o.tlsClientConfig,
+				// We negotiate and decode gzip/deflate ourselves in DoHTTPStreamedCommunication
+				// so that deflate gets the same transparent handling as gzip; left at its
+				// default, http.Transport only auto-decodes gzip, and only when the request
+				// doesn't set its own Accept-Encoding.
+				DisableCompression: true,
+			},
+		},
+		passthroughEncoding: o.passthroughEncoding,
+	}
+}
+
+// defaultClient is used by the package-level helpers (DoHTTPCommunication, DoProtoHTTPCommunication,
+// DownloadURLToPath, DownloadAndUpload) whenever the caller passes a nil *Client, preserving their
+// previous http.DefaultClient-based behavior: pooled connections same as NewClient's other
+// defaults, but, like http.DefaultClient, no overall per-call timeout. Callers that want NewClient's
+// 30s default timeout too should construct their own *Client and pass it explicitly.
+var defaultClient = NewClient(WithTimeout(0))
+
+// orDefault returns c, or the package-level defaultClient when c is nil.
+func (c *Client) orDefault() *Client {
+	if c == nil {
+		return defaultClient
+	}
+	return c
+}
@@ -19,6 +19,8 @@ import (
 // - req: Proto message to be used as request body. (if nil, then no body is sent)
 // - reqHeaders: Headers to set with HTTP request.
 // - resp: on success, the response is decoded and set to this. (if nil, then no response body is expected)
+// - client: Client to send the request with. (if nil, the package-level default client is used)
+// - retrier: Retrier to retry transient failures with. (if nil, the request is attempted once)
 //
 // Returns:
 // - statusCode: HTTP status code.
@@ -29,6 +31,8 @@ func DoProtoHTTPCommunication(
 	req protoreflect.ProtoMessage,
 	reqHeaders map[string]string,
 	resp protoreflect.ProtoMessage,
+	client *Client,
+	retrier *Retrier,
 ) (statusCode int, err error) {
 	var reqBody []byte
 	if req != nil {
@@ -46,7 +50,9 @@ func DoProtoHTTPCommunication(
 		url,
 		httpMethod,
 		bytes.NewReader(reqBody),
-		reqHeaders)
+		reqHeaders,
+		client,
+		retrier)
 	if err != nil {
 		return 0, err
 	}
@@ -68,6 +74,10 @@ func DoProtoHTTPCommunication(
 // - httpMethod: HTTP method used to send request.
 // - reqBody: message body to send with HTTP request. (if nil, then no body is sent)
 // - reqHeaders: Headers to set with HTTP request.
+// - client: Client to send the request with. (if nil, the package-level default client is used)
+// - retrier: Retrier to retry transient failures with. (if nil, the request is attempted once)
+//   reqBody must be nil, or implement io.Seeker, for retries to work: it is rewound to the start
+//   before each attempt.
 //
 // Returns:
 // - statusCode: HTTP status code.
@@ -78,33 +88,44 @@ func DoHTTPCommunication(
 	url, httpMethod string,
 	reqBody io.Reader,
 	reqHeaders map[string]string,
+	client *Client,
+	retrier *Retrier,
 ) (statusCode int, respBody []byte, err error) {
-	r, err := http.NewRequestWithContext(ctx, httpMethod, url, reqBody)
-	if err != nil {
-		return 0, nil, err
-	}
+	attempt := func() Attempt {
+		if seeker, ok := reqBody.(io.Seeker); ok {
+			if _, serr := seeker.Seek(0, io.SeekStart); serr != nil {
+				return Attempt{Err: serr}
+			}
+		}
 
-	for k, v := range reqHeaders {
-		r.Header.Add(k, v)
-	}
+		var streamedRespBody io.ReadCloser
+		statusCode, streamedRespBody, err = DoHTTPStreamedCommunication(
+			ctx,
+			url,
+			httpMethod,
+			reqBody,
+			reqHeaders,
+			client)
+		if err != nil {
+			return Attempt{Err: err, StatusCode: statusCode}
+		}
+		defer streamedRespBody.Close()
 
-	statusCode, streamedRespBody, err := DoHTTPStreamedCommunication(
-		ctx,
-		url,
-		httpMethod,
-		reqBody,
-		reqHeaders)
-	if err != nil {
-		return statusCode, nil, err
+		respBody, err = io.ReadAll(streamedRespBody)
+		return Attempt{Err: err, StatusCode: statusCode}
 	}
 
-	defer streamedRespBody.Close()
+	if retrier == nil {
+		if a := attempt(); a.Err != nil {
+			return statusCode, nil, a.Err
+		}
+		return statusCode, respBody, nil
+	}
 
-	b, err := io.ReadAll(streamedRespBody)
-	if err != nil {
+	if err := retrier.Do(ctx, attempt); err != nil {
 		return statusCode, nil, err
 	}
-	return statusCode, b, nil
+	return statusCode, respBody, nil
 }
 
 // DoHTTPStreamedCommunication is a thin-wrapper over basic HTTP communication where the response is not read
@@ -115,6 +136,7 @@ func DoHTTPCommunication(
 // - httpMethod: HTTP method used to send request.
 // - reqBody: message body to send with HTTP request. (if nil, then no body is sent)
 // - reqHeaders: Headers to set with HTTP request.
+// - client: Client to send the request with. (if nil, the package-level default client is used)
 //
 // Returns:
 // - statusCode: HTTP status code.
@@ -125,7 +147,10 @@ func DoHTTPStreamedCommunication(
 	url, httpMethod string,
 	reqBody io.Reader,
 	reqHeaders map[string]string,
+	client *Client,
 ) (statusCode int, respBody io.ReadCloser, err error) {
+	c := client.orDefault()
+
 	r, err := http.NewRequestWithContext(
 		ctx,
 		httpMethod,
@@ -138,22 +163,28 @@ func DoHTTPStreamedCommunication(
 	for k, v := range reqHeaders {
 		r.Header.Add(k, v)
 	}
+	applyAcceptEncoding(c, r)
 
-	rsp, err := http.DefaultClient.Do(r)
+	rsp, err := c.httpClient.Do(r)
 	if err != nil {
 		return 0, nil, err
 	}
 
+	rspBody, err := decodeResponse(c, rsp)
+	if err != nil {
+		return rsp.StatusCode, nil, err
+	}
+
 	// Success response status codes will lie between 200-299 and below 200 is informational we ignore those as well.
 	if rsp.StatusCode >= 300 {
-		defer rsp.Body.Close()
+		defer rspBody.Close()
 
-		b, err := io.ReadAll(rsp.Body)
+		b, err := io.ReadAll(rspBody)
 		if err != nil {
 			return rsp.StatusCode, nil, err
 		}
 		return rsp.StatusCode, nil, fmt.Errorf("resp body : %v", string(b))
 	}
 
-	return rsp.StatusCode, rsp.Body, nil
+	return rsp.StatusCode, rspBody, nil
 }
@@ -3,7 +3,6 @@ package utils
 import (
 	"context"
 	"fmt"
-	"time"
 
 	"cloud.google.com/go/longrunning/autogen/longrunningpb"
 	"google.golang.org/grpc/status"
@@ -16,6 +15,8 @@ type LongrunningOperation[Req, Resp protoreflect.ProtoMessage] struct {
 	OperationName  string
 	OperationError error
 	OperationDone  bool
+	// Metadata is the most recent metadata payload reported by the operation, if any.
+	Metadata *anypb.Any
 
 	Request  *CustomProtoMessage[Req]
 	Response *CustomProtoMessage[Resp]
@@ -57,12 +58,15 @@ func (l *LongrunningOperation[Req, Resp]) Process(ctx context.Context) (bool, er
 		})
 	}
 	if err != nil {
-		l.OperationError = err
-		return true, err
+		// A transport-level failure of this attempt, not a terminal state for the operation
+		// itself: leave OperationDone/OperationError unset so a caller (e.g. Poller) can retry
+		// the trigger/get call on the next Process call.
+		return false, err
 	}
 
 	l.OperationName = opResult.GetName()
 	l.OperationDone = opResult.GetDone()
+	l.Metadata = opResult.GetMetadata()
 
 	if !l.OperationDone {
 		return false, nil
@@ -104,25 +108,35 @@ func (l *LongrunningOperation[Req, Resp]) GetResponse() Resp {
 	return l.Response.Msg
 }
 
+// ProcessLongRunningOperationToCompletion polls l to completion using a Poller with the default
+// backoff policy (see NewPoller), returning l's terminal error, if any (including the original
+// status.Error surfaced by the operation itself) instead of masking it behind a generic timeout.
+// Passing a nil poller uses NewPoller[Req, Resp](nil); to cancel the remote operation when ctx is
+// canceled, construct a Poller with a cancel func instead.
 func ProcessLongRunningOperationToCompletion[
 	Req protoreflect.ProtoMessage,
 	Resp protoreflect.ProtoMessage,
 ](
 	ctx context.Context,
 	l *LongrunningOperation[Req, Resp],
+	poller *Poller[Req, Resp],
 ) error {
-	var (
-		innererr error
-		isdone   bool
-	)
-	if err := RetryFunc(time.Minute*10, func() error {
-		isdone, innererr = l.Process(ctx)
-		if !isdone {
-			return fmt.Errorf("trying continues")
+	if poller == nil {
+		poller = NewPoller[Req, Resp](nil)
+	}
+
+	var lastErr error
+	for ev := range poller.Watch(ctx, l) {
+		lastErr = ev.Err
+		if ev.Done {
+			return ev.Err
 		}
-		return nil
-	}); err != nil {
-		return fmt.Errorf("timed-out while retrying")
 	}
-	return innererr
+	if ctx.Err() != nil {
+		return fmt.Errorf("context done while waiting for operation %q to complete: %w", l.OperationName, ctx.Err())
+	}
+	if lastErr == nil && !l.OperationDone {
+		return fmt.Errorf("polling stopped before operation %q completed", l.OperationName)
+	}
+	return lastErr
 }
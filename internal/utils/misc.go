@@ -10,42 +10,19 @@ import (
 	"net/http"
 	"os"
 	"strings"
-	"time"
 
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
 )
 
-func RetryFunc(
-	timeout time.Duration,
-	fn func() error,
-) error {
-	var err error
-	deadline := time.Now().Add(timeout)
-	// Initial time to sleep between tries.
-	pause := 50 * time.Millisecond
-	// Cutoff for exponential backoff.
-	maxPause := 1 * time.Second
-	for tryCount := 0; time.Until(deadline) >= 0; {
-		if err = fn(); err == nil {
-			return nil
-		}
-
-		time.Sleep(pause)
-		pause = 2 * pause
-		if pause > maxPause {
-			pause = maxPause
-		}
-		tryCount++
-		fmt.Printf("RetryFunc: try [%v], error: %v\n", tryCount, err)
-	}
-	return err
-}
-
-func DownloadURLToPath(
+// downloadSingleShot is the original, non-resumable DownloadURLToPath behavior: one GET,
+// streamed straight into a freshly created file. DownloadURLToPath falls back to it whenever the
+// HEAD probe fails or the server doesn't advertise range support.
+func downloadSingleShot(
 	ctx context.Context,
 	filePath string,
 	downloadURL string,
+	client *Client,
 ) error {
 	out, err := os.Create(filePath)
 	if err != nil {
@@ -58,7 +35,8 @@ func DownloadURLToPath(
 		downloadURL,
 		http.MethodGet,
 		http.NoBody,
-		nil)
+		nil,
+		client)
 	if err != nil {
 		return fmt.Errorf("DownloadURLToPath: %w", err)
 	}
@@ -81,25 +59,39 @@ func DownloadURLToPath(
 	return nil
 }
 
-// Uses Get to download and PUT to upload
+// Uses Get to download and PUT to upload.
+//
+// When resumable is non-nil, and the source turns out to be larger than one chunk, the transfer
+// is sent as a sequence of Content-Range PUTs via ResumableUpload instead of a single streaming
+// PUT, so it can survive a mid-transfer failure. Pass nil to always use the single-shot path.
 func DownloadAndUpload(
 	ctx context.Context,
 	downloadURL string,
 	downloadHeaders map[string]string,
 	uploadURL string,
 	uploadHeaders map[string]string,
+	client *Client,
+	resumable *ResumableUpload,
 ) error {
 	if downloadURL == "" || uploadURL == "" {
 		// nothing to do
 		return nil
 	}
 
+	if resumable != nil {
+		if ok, err := resumableDownloadAndUpload(ctx, downloadURL, downloadHeaders, uploadURL, uploadHeaders, client, resumable); ok {
+			return err
+		}
+		// Source size unknown or smaller than one chunk: fall through to the single-shot path.
+	}
+
 	statusCode, downloadRespBody, err := DoHTTPStreamedCommunication(
 		ctx,
 		downloadURL,
 		http.MethodGet,
 		http.NoBody,
-		downloadHeaders)
+		downloadHeaders,
+		client)
 	if err != nil {
 		return fmt.Errorf("DownloadAndUpload: %w", err)
 	}
@@ -119,7 +111,8 @@ func DownloadAndUpload(
 		uploadURL,
 		http.MethodPut,
 		downloadRespBody,
-		uploadHeaders)
+		uploadHeaders,
+		client)
 	if err != nil {
 		return fmt.Errorf("DownloadAndUpload: %w", err)
 	}
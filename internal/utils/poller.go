@@ -0,0 +1,165 @@
+package utils
+
+import (
+	"context"
+	"time"
+
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// Event is one update emitted by Poller.Watch as a LongrunningOperation is polled.
+type Event struct {
+	Metadata *anypb.Any
+	Done     bool
+	Err      error
+}
+
+// Poller drives a LongrunningOperation to completion with server-hinted, jittered exponential
+// backoff between get calls, and can request server-side cancellation of the remote operation
+// when the caller's context is canceled mid-poll.
+type Poller[Req, Resp protoreflect.ProtoMessage] struct {
+	// Retrier controls the backoff between polls: Base and Cap default independently to 1s/30s
+	// (not Retrier.Do's 100ms/1s), and MaxAttempts/Deadline bound the poll the same way Retrier.Do does.
+	// Retrier.Classify is ignored; Watch otherwise keeps polling until the operation is done, a
+	// transport failure is judged non-retryable, or ctx is canceled.
+	Retrier *Retrier
+
+	cancel func(ctx context.Context, req *longrunningpb.CancelOperationRequest) (*emptypb.Empty, error)
+}
+
+// NewPoller builds a Poller with the default backoff policy (1s base, 30s cap). cancel, if
+// non-nil, should invoke the LRO service's CancelOperation RPC; it is called by
+// Poller.CancelOperation, and automatically by Watch when ctx is canceled before the operation
+// completes.
+func NewPoller[Req, Resp protoreflect.ProtoMessage](
+	cancel func(ctx context.Context, req *longrunningpb.CancelOperationRequest) (*emptypb.Empty, error),
+) *Poller[Req, Resp] {
+	return &Poller[Req, Resp]{
+		Retrier: &Retrier{Base: time.Second, Cap: 30 * time.Second},
+		cancel:  cancel,
+	}
+}
+
+// CancelOperation calls the LRO CancelOperation RPC for the given operation name. It is a no-op
+// if the Poller was built without a cancel func, or operationName is empty.
+func (p *Poller[Req, Resp]) CancelOperation(ctx context.Context, operationName string) error {
+	if p.cancel == nil || operationName == "" {
+		return nil
+	}
+	_, err := p.cancel(ctx, &longrunningpb.CancelOperationRequest{Name: operationName})
+	return err
+}
+
+// Watch polls l until it reaches a terminal state (done, or a non-retryable transport failure),
+// Retrier.MaxAttempts/Retrier.Deadline is reached, or ctx is canceled, emitting an Event after
+// every poll. The returned channel is closed once polling stops, after which
+// l.OperationDone/l.OperationError hold the final outcome (when polling stopped due to completion
+// rather than cancellation or exhaustion). If ctx is canceled before the operation completes,
+// Watch calls CancelOperation with a background context before returning.
+func (p *Poller[Req, Resp]) Watch(ctx context.Context, l *LongrunningOperation[Req, Resp]) <-chan Event {
+	events := make(chan Event)
+
+	r := Retrier{Base: time.Second, Cap: 30 * time.Second}
+	if p.Retrier != nil {
+		r = *p.Retrier
+		if r.Base <= 0 {
+			r.Base = time.Second
+		}
+		if r.Cap <= 0 {
+			r.Cap = 30 * time.Second
+		}
+	}
+
+	go func() {
+		defer close(events)
+
+		for attempt := 0; r.MaxAttempts <= 0 || attempt < r.MaxAttempts; attempt++ {
+			isdone, err := l.Process(ctx)
+
+			select {
+			case events <- Event{Metadata: l.Metadata, Done: isdone, Err: err}:
+			case <-ctx.Done():
+				p.cancelQuietly(l.OperationName)
+				return
+			}
+
+			if isdone {
+				return
+			}
+			if ctx.Err() != nil {
+				p.cancelQuietly(l.OperationName)
+				return
+			}
+			if err != nil && classifyLROTransportError(err) != Retry {
+				return
+			}
+			if !r.Deadline.IsZero() && !time.Now().Before(r.Deadline) {
+				return
+			}
+
+			sleep, hinted := retryAfterFromError(err)
+			if !hinted {
+				sleep = fullJitterBackoff(r.Base, r.Cap, attempt)
+			}
+
+			select {
+			case <-ctx.Done():
+				p.cancelQuietly(l.OperationName)
+				return
+			case <-time.After(sleep):
+			}
+		}
+	}()
+
+	return events
+}
+
+// cancelQuietly best-effort cancels the remote operation with a fresh context, since ctx itself
+// is already done by the time this is called.
+func (p *Poller[Req, Resp]) cancelQuietly(operationName string) {
+	if p.cancel == nil || operationName == "" {
+		return
+	}
+	_ = p.CancelOperation(context.Background(), operationName)
+}
+
+// classifyLROTransportError decides whether a Process failure is worth retrying: transient gRPC
+// codes (Unavailable, ResourceExhausted, Aborted, DeadlineExceeded) are retried, everything else
+// (including a business failure surfaced via opResult.GetError(), which arrives with isdone
+// true and never reaches this classifier) is treated as permanent.
+func classifyLROTransportError(err error) Decision {
+	st, ok := status.FromError(err)
+	if !ok {
+		return StopPermanent
+	}
+	switch st.Code() {
+	case codes.Unavailable, codes.ResourceExhausted, codes.Aborted, codes.DeadlineExceeded:
+		return Retry
+	default:
+		return StopPermanent
+	}
+}
+
+// retryAfterFromError looks for a google.rpc.RetryInfo detail on a gRPC status error (the
+// gRPC equivalent of an HTTP Retry-After header) and returns its delay.
+func retryAfterFromError(err error) (time.Duration, bool) {
+	if err == nil {
+		return 0, false
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return 0, false
+	}
+	for _, d := range st.Details() {
+		if ri, ok := d.(*errdetails.RetryInfo); ok && ri.GetRetryDelay() != nil {
+			return ri.GetRetryDelay().AsDuration(), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,294 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DownloadOptions configures DownloadURLToPath's resumable behavior.
+type DownloadOptions struct {
+	// Parallelism splits a fresh (non-resumed) download across this many concurrent Range
+	// workers once the HEAD probe confirms the server advertises Accept-Ranges: bytes. Defaults
+	// to 1 (sequential). Ignored when resuming a partial file, which always continues as a
+	// single worker.
+	Parallelism int
+}
+
+// downloadProbe is what a HEAD request tells us about a resource before we start pulling bytes.
+type downloadProbe struct {
+	contentLength int64
+	etag          string
+	lastModified  string
+	acceptsRanges bool
+}
+
+// DownloadURLToPath downloads downloadURL to filePath. It first issues a HEAD request to learn
+// the expected size and whether the server supports byte ranges (Accept-Ranges: bytes). If
+// filePath already exists with a size smaller than the expected length and ranges are supported,
+// the download resumes from that offset via `Range: bytes=N-` (guarded with `If-Range` against
+// the resource changing underneath it); a 206 response's Content-Range total is checked against
+// the HEAD-reported length before the new bytes are appended, and a 200 response (meaning the
+// server ignored the range) truncates and restarts the file. opts may be nil to use the
+// defaults; when HEAD probing fails or ranges aren't supported, this falls back to a plain,
+// non-resumable GET.
+func DownloadURLToPath(
+	ctx context.Context,
+	filePath string,
+	downloadURL string,
+	client *Client,
+	opts *DownloadOptions,
+) error {
+	probe, err := probeDownload(ctx, client, downloadURL)
+	if err != nil || !probe.acceptsRanges || probe.contentLength <= 0 {
+		return downloadSingleShot(ctx, filePath, downloadURL, client)
+	}
+
+	var startOffset int64
+	if existing, statErr := os.Stat(filePath); statErr == nil {
+		switch {
+		case existing.Size() >= probe.contentLength:
+			// Already complete.
+			return nil
+		case existing.Size() > 0:
+			startOffset = existing.Size()
+		}
+	}
+
+	parallelism := 1
+	if opts != nil && opts.Parallelism > 1 {
+		parallelism = opts.Parallelism
+	}
+
+	out, err := openForDownload(filePath, startOffset)
+	if err != nil {
+		return fmt.Errorf("DownloadURLToPath: %w", err)
+	}
+	defer out.Close()
+
+	if startOffset > 0 || parallelism <= 1 {
+		if err := downloadRange(ctx, out, downloadURL, client, probe, startOffset, probe.contentLength-1); err != nil {
+			return fmt.Errorf("DownloadURLToPath: %w", err)
+		}
+		return nil
+	}
+
+	if err := downloadParallel(ctx, out, downloadURL, client, probe, parallelism); err != nil {
+		return fmt.Errorf("DownloadURLToPath: %w", err)
+	}
+	return nil
+}
+
+// openForDownload opens filePath for writing, truncating it when starting from scratch or
+// opening it for in-place append when resuming from startOffset.
+func openForDownload(filePath string, startOffset int64) (*os.File, error) {
+	if startOffset == 0 {
+		return os.Create(filePath)
+	}
+	return os.OpenFile(filePath, os.O_WRONLY, 0o644)
+}
+
+// probeDownload issues a HEAD request to learn the resource's size, validators, and range
+// support ahead of downloading it.
+func probeDownload(ctx context.Context, client *Client, downloadURL string) (downloadProbe, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, downloadURL, http.NoBody)
+	if err != nil {
+		return downloadProbe{}, err
+	}
+
+	requestIdentityEncoding(req)
+
+	rsp, err := client.orDefault().httpClient.Do(req)
+	if err != nil {
+		return downloadProbe{}, err
+	}
+	// A HEAD response never carries a body, so there's nothing to decode here regardless.
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode >= 300 {
+		return downloadProbe{}, fmt.Errorf("HEAD %v failed with status: %v", downloadURL, rsp.StatusCode)
+	}
+
+	return downloadProbe{
+		contentLength: rsp.ContentLength,
+		etag:          rsp.Header.Get("ETag"),
+		lastModified:  rsp.Header.Get("Last-Modified"),
+		acceptsRanges: strings.EqualFold(rsp.Header.Get("Accept-Ranges"), "bytes"),
+	}, nil
+}
+
+// ifRangeValidator picks the strongest validator (ETag if present, else Last-Modified) to send
+// as If-Range, so a resumed download restarts from scratch if the resource changed underneath it
+// rather than stitching together bytes from two different versions.
+func ifRangeValidator(probe downloadProbe) string {
+	if probe.etag != "" {
+		return probe.etag
+	}
+	return probe.lastModified
+}
+
+// downloadRange GETs bytes [start, end] of downloadURL and writes them into out starting at
+// start, handling the server ignoring the range (200) by truncating and restarting from scratch.
+func downloadRange(
+	ctx context.Context,
+	out *os.File,
+	downloadURL string,
+	client *Client,
+	probe downloadProbe,
+	start, end int64,
+) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, downloadURL, http.NoBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+	if v := ifRangeValidator(probe); v != "" {
+		req.Header.Set("If-Range", v)
+	}
+
+	requestIdentityEncoding(req)
+
+	rsp, err := client.orDefault().httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+
+	switch rsp.StatusCode {
+	case http.StatusPartialContent:
+		total, err := parseContentRangeTotal(rsp.Header.Get("Content-Range"))
+		if err != nil {
+			return fmt.Errorf("parse Content-Range: %w", err)
+		}
+		if total != probe.contentLength {
+			return fmt.Errorf("resumed content length (%d) does not match the original probe (%d); source likely changed", total, probe.contentLength)
+		}
+		if _, err := out.Seek(start, io.SeekStart); err != nil {
+			return err
+		}
+		_, err = io.Copy(out, rsp.Body)
+		return err
+	case http.StatusOK:
+		// Range was ignored; restart the file from scratch.
+		if err := out.Truncate(0); err != nil {
+			return err
+		}
+		if _, err := out.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		_, err = io.Copy(out, rsp.Body)
+		return err
+	default:
+		b, _ := io.ReadAll(rsp.Body)
+		return fmt.Errorf("download failed with (status: %v, response: %v)", rsp.StatusCode, string(b))
+	}
+}
+
+// downloadParallel preallocates out to probe.contentLength and splits the download across
+// parallelism concurrent Range workers, each writing its slice via WriteAt.
+func downloadParallel(
+	ctx context.Context,
+	out *os.File,
+	downloadURL string,
+	client *Client,
+	probe downloadProbe,
+	parallelism int,
+) error {
+	if err := out.Truncate(probe.contentLength); err != nil {
+		return err
+	}
+
+	chunk := probe.contentLength / int64(parallelism)
+	if chunk <= 0 {
+		chunk = probe.contentLength
+		parallelism = 1
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, parallelism)
+	for i := 0; i < parallelism; i++ {
+		start := int64(i) * chunk
+		end := start + chunk - 1
+		if i == parallelism-1 {
+			end = probe.contentLength - 1
+		}
+
+		wg.Add(1)
+		go func(i int, start, end int64) {
+			defer wg.Done()
+			errs[i] = downloadRangeAt(ctx, out, downloadURL, client, probe, start, end)
+		}(i, start, end)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// downloadRangeAt GETs bytes [start, end] and writes them at that offset via WriteAt, for use by
+// a parallel worker sharing one preallocated file with others.
+func downloadRangeAt(
+	ctx context.Context,
+	out *os.File,
+	downloadURL string,
+	client *Client,
+	probe downloadProbe,
+	start, end int64,
+) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, downloadURL, http.NoBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+	if v := ifRangeValidator(probe); v != "" {
+		req.Header.Set("If-Range", v)
+	}
+
+	requestIdentityEncoding(req)
+
+	rsp, err := client.orDefault().httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode != http.StatusPartialContent {
+		b, _ := io.ReadAll(rsp.Body)
+		return fmt.Errorf("range worker [%d-%d] failed with (status: %v, response: %v)", start, end, rsp.StatusCode, string(b))
+	}
+
+	_, err = io.Copy(&offsetWriter{f: out, offset: start}, rsp.Body)
+	return err
+}
+
+// offsetWriter adapts an io.WriterAt to io.Writer for a single advancing offset, so io.Copy can
+// stream sequentially into a pre-sized file from one range worker.
+type offsetWriter struct {
+	f      io.WriterAt
+	offset int64
+}
+
+func (w *offsetWriter) Write(p []byte) (int, error) {
+	n, err := w.f.WriteAt(p, w.offset)
+	w.offset += int64(n)
+	return n, err
+}
+
+// parseContentRangeTotal extracts the total length from a "bytes start-end/total" Content-Range
+// header value.
+func parseContentRangeTotal(contentRange string) (int64, error) {
+	idx := strings.LastIndex(contentRange, "/")
+	if idx < 0 || idx+1 >= len(contentRange) {
+		return 0, fmt.Errorf("malformed Content-Range %q", contentRange)
+	}
+	return strconv.ParseInt(contentRange[idx+1:], 10, 64)
+}
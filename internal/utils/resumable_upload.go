@@ -0,0 +1,340 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultResumableChunkSize is the amount of the source buffered and PUT per chunk when a caller
+// does not override ResumableUpload.ChunkSize.
+const defaultResumableChunkSize = 8 * 1024 * 1024 // 8 MiB
+
+// errChunkRejected marks a chunk failure that is neither a 5xx nor a network error, so
+// putChunkWithRetry does not retry it.
+var errChunkRejected = errors.New("chunk rejected")
+
+// ResumableUploadState is the information a caller needs to persist in order to resume an
+// interrupted transfer in a later process: the session URI handed out by the upload endpoint,
+// and the byte offset the server has confirmed receiving so far.
+type ResumableUploadState struct {
+	SessionURI string
+	Offset     int64
+}
+
+// SourceOpener opens the upload source starting at the given byte offset. It is called once to
+// begin the upload and again whenever a chunk has to be retried after the reader already
+// consumed from a prior, failed attempt.
+type SourceOpener func(ctx context.Context, offset int64) (io.ReadCloser, error)
+
+// ResumableUpload drives a large PUT upload as a sequence of `Content-Range` chunks instead of a
+// single streaming PUT, modeled on the session-URI negotiation used by Google API resumable
+// uploads (gensupport/resumable.go):
+//
+//  1. A POST to uploadURL carrying X-Upload-Content-Length obtains a session URI from the
+//     Location response header (Start).
+//  2. Each chunk is PUT against that session URI with Content-Range: bytes {off}-{off+n-1}/{total
+//     or *}.
+//  3. A 308 ("Resume Incomplete") response is progress, not failure: the Range response header
+//     reports the server's confirmed offset and the upload continues from there.
+//  4. 5xx and network errors retry the current chunk with exponential backoff.
+//  5. A 200/201 response ends the upload.
+type ResumableUpload struct {
+	// ChunkSize bounds how much of the source is buffered and sent per PUT. Defaults to 8 MiB.
+	ChunkSize int64
+	// MaxAttempts bounds how many times a single chunk is attempted, counting the first try, when
+	// it fails with a 5xx or network error. Defaults to 5.
+	MaxAttempts int
+	// OnProgress, if set, is called with the confirmed state after every chunk the server accepts.
+	OnProgress func(state ResumableUploadState)
+
+	Client *Client
+}
+
+// Start begins a resumable upload session, returning the session URI the remaining chunks must
+// be PUT against. total may be zero if the source size is not known ahead of time.
+func (r *ResumableUpload) Start(
+	ctx context.Context,
+	uploadURL string,
+	headers map[string]string,
+	total int64,
+) (ResumableUploadState, error) {
+	reqHeaders := CaseInsensitiveKeyMapJoin(headers, nil)
+	if total > 0 {
+		reqHeaders = CaseInsensitiveKeyMapJoin(reqHeaders, map[string]string{
+			"X-Upload-Content-Length": strconv.FormatInt(total, 10),
+		})
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uploadURL, http.NoBody)
+	if err != nil {
+		return ResumableUploadState{}, fmt.Errorf("ResumableUpload.Start: %w", err)
+	}
+	for k, v := range reqHeaders {
+		req.Header.Add(k, v)
+	}
+
+	rsp, err := r.Client.orDefault().httpClient.Do(req)
+	if err != nil {
+		return ResumableUploadState{}, fmt.Errorf("ResumableUpload.Start: %w", err)
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode >= 300 {
+		b, _ := io.ReadAll(rsp.Body)
+		return ResumableUploadState{}, fmt.Errorf("ResumableUpload.Start: failed with (status: %v, response: %v)", rsp.StatusCode, string(b))
+	}
+
+	sessionURI := rsp.Header.Get("Location")
+	if sessionURI == "" {
+		return ResumableUploadState{}, fmt.Errorf("ResumableUpload.Start: no Location header in response")
+	}
+
+	return ResumableUploadState{SessionURI: sessionURI}, nil
+}
+
+// Upload sends the source in ChunkSize pieces against a session already obtained via Start,
+// resuming from state.Offset. total is the full source size, or 0 if unknown (in which case the
+// Content-Range total is sent as "*" until the final chunk).
+func (r *ResumableUpload) Upload(
+	ctx context.Context,
+	state ResumableUploadState,
+	open SourceOpener,
+	total int64,
+) (ResumableUploadState, error) {
+	chunkSize := r.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = defaultResumableChunkSize
+	}
+	maxAttempts := r.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 5
+	}
+
+	for {
+		src, err := open(ctx, state.Offset)
+		if err != nil {
+			return state, fmt.Errorf("ResumableUpload.Upload: %w", err)
+		}
+
+		buf := make([]byte, chunkSize)
+		n, readErr := io.ReadFull(src, buf)
+		src.Close()
+		if readErr != nil && readErr != io.ErrUnexpectedEOF && readErr != io.EOF {
+			return state, fmt.Errorf("ResumableUpload.Upload: %w", readErr)
+		}
+		chunk := buf[:n]
+		last := readErr == io.ErrUnexpectedEOF || readErr == io.EOF
+
+		totalStr := "*"
+		if total > 0 {
+			totalStr = strconv.FormatInt(total, 10)
+		} else if last {
+			totalStr = strconv.FormatInt(state.Offset+int64(n), 10)
+		}
+
+		var rangeHeader string
+		if n > 0 {
+			rangeHeader = fmt.Sprintf("bytes %d-%d/%s", state.Offset, state.Offset+int64(n)-1, totalStr)
+		} else {
+			rangeHeader = fmt.Sprintf("bytes */%s", totalStr)
+		}
+
+		newOffset, done, err := r.putChunkWithRetry(ctx, state.SessionURI, chunk, rangeHeader, state.Offset+int64(n), maxAttempts)
+		if err != nil {
+			return state, fmt.Errorf("ResumableUpload.Upload: %w", err)
+		}
+
+		state.Offset = newOffset
+		if r.OnProgress != nil {
+			r.OnProgress(state)
+		}
+
+		if done {
+			return state, nil
+		}
+		if last && n == 0 {
+			return state, fmt.Errorf("ResumableUpload.Upload: source exhausted before server confirmed completion")
+		}
+	}
+}
+
+// putChunkWithRetry PUTs a single chunk, retrying 5xx/network failures with exponential backoff,
+// and returns the offset the server has confirmed plus whether the upload is complete.
+func (r *ResumableUpload) putChunkWithRetry(
+	ctx context.Context,
+	sessionURI string,
+	chunk []byte,
+	rangeHeader string,
+	nextOffset int64,
+	maxAttempts int,
+) (offset int64, done bool, err error) {
+	pause := 250 * time.Millisecond
+	const maxPause = 10 * time.Second
+
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		offset, done, err = r.putChunk(ctx, sessionURI, chunk, rangeHeader, nextOffset)
+		if err == nil {
+			return offset, done, nil
+		}
+		if errors.Is(err, errChunkRejected) || attempt == maxAttempts-1 {
+			break
+		}
+
+		sleep := time.Duration(rand.Int63n(int64(pause)))
+		select {
+		case <-ctx.Done():
+			return 0, false, ctx.Err()
+		case <-time.After(sleep):
+		}
+		pause *= 2
+		if pause > maxPause {
+			pause = maxPause
+		}
+	}
+	return 0, false, err
+}
+
+// putChunk issues a single PUT for one chunk and interprets the response: 200/201 means the
+// upload is complete (confirmed offset is nextOffset, the offset immediately past the bytes just
+// sent — including the zero-byte "bytes */N" confirmation PUT, whose Content-Range doesn't carry
+// a byte range to parse), 308 means progress (the Range header reports the confirmed offset), and
+// anything else is an error.
+func (r *ResumableUpload) putChunk(
+	ctx context.Context,
+	sessionURI string,
+	chunk []byte,
+	rangeHeader string,
+	nextOffset int64,
+) (offset int64, done bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, sessionURI, bytes.NewReader(chunk))
+	if err != nil {
+		return 0, false, err
+	}
+	req.Header.Set("Content-Range", rangeHeader)
+	req.ContentLength = int64(len(chunk))
+
+	rsp, err := r.Client.orDefault().httpClient.Do(req)
+	if err != nil {
+		return 0, false, err
+	}
+	defer rsp.Body.Close()
+
+	switch {
+	case rsp.StatusCode == http.StatusOK || rsp.StatusCode == http.StatusCreated:
+		return nextOffset, true, nil
+	case rsp.StatusCode == 308:
+		confirmed, parseErr := parseConfirmedOffset(rsp.Header.Get("Range"))
+		if parseErr != nil {
+			return 0, false, fmt.Errorf("%w: parse Range header: %v", errChunkRejected, parseErr)
+		}
+		return confirmed, false, nil
+	case rsp.StatusCode >= 500:
+		b, _ := io.ReadAll(rsp.Body)
+		return 0, false, fmt.Errorf("chunk upload failed with (status: %v, response: %v)", rsp.StatusCode, string(b))
+	default:
+		b, _ := io.ReadAll(rsp.Body)
+		return 0, false, fmt.Errorf("%w: unexpected chunk upload status (status: %v, response: %v)", errChunkRejected, rsp.StatusCode, string(b))
+	}
+}
+
+// resumableDownloadAndUpload drives DownloadAndUpload's resumable path: it HEADs downloadURL to
+// learn the source size, and if that size exceeds one chunk, relays it to uploadURL as a
+// sequence of Content-Range PUTs, re-issuing a ranged GET against downloadURL to (re)open the
+// source at whatever offset a chunk attempt needs. It reports handled=false when the source size
+// is unknown or fits in a single chunk, so the caller can fall back to the single-shot path.
+func resumableDownloadAndUpload(
+	ctx context.Context,
+	downloadURL string,
+	downloadHeaders map[string]string,
+	uploadURL string,
+	uploadHeaders map[string]string,
+	client *Client,
+	resumable *ResumableUpload,
+) (handled bool, err error) {
+	headReq, err := http.NewRequestWithContext(ctx, http.MethodHead, downloadURL, http.NoBody)
+	if err != nil {
+		return false, fmt.Errorf("resumableDownloadAndUpload: %w", err)
+	}
+	for k, v := range downloadHeaders {
+		headReq.Header.Add(k, v)
+	}
+	requestIdentityEncoding(headReq)
+
+	headRsp, err := client.orDefault().httpClient.Do(headReq)
+	if err != nil {
+		return false, fmt.Errorf("resumableDownloadAndUpload: %w", err)
+	}
+	headRsp.Body.Close()
+
+	chunkSize := resumable.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = defaultResumableChunkSize
+	}
+	total := headRsp.ContentLength
+	if total <= 0 || total <= chunkSize {
+		return false, nil
+	}
+
+	open := func(ctx context.Context, offset int64) (io.ReadCloser, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, downloadURL, http.NoBody)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range downloadHeaders {
+			req.Header.Add(k, v)
+		}
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		requestIdentityEncoding(req)
+
+		rsp, err := client.orDefault().httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if rsp.StatusCode != http.StatusPartialContent {
+			defer rsp.Body.Close()
+			b, _ := io.ReadAll(rsp.Body)
+			return nil, fmt.Errorf("download range failed with (status: %v, response: %v)", rsp.StatusCode, string(b))
+		}
+		return rsp.Body, nil
+	}
+
+	resumable.Client = client
+
+	state, err := resumable.Start(ctx, uploadURL, uploadHeaders, total)
+	if err != nil {
+		return true, fmt.Errorf("DownloadAndUpload: %w", err)
+	}
+
+	if _, err := resumable.Upload(ctx, state, open, total); err != nil {
+		return true, fmt.Errorf("DownloadAndUpload: %w", err)
+	}
+
+	fmt.Printf("DownloadAndUpload stats === \ndownloaded from - %v\nuploaded to - %v\n\n", downloadURL, uploadURL)
+	return true, nil
+}
+
+// parseConfirmedOffset parses a "bytes=0-K" Range response header (as returned with HTTP 308
+// Resume Incomplete) and returns K+1, the offset to resume from.
+func parseConfirmedOffset(rangeHeader string) (int64, error) {
+	if rangeHeader == "" {
+		// No bytes confirmed yet.
+		return 0, nil
+	}
+	parts := strings.SplitN(strings.TrimPrefix(rangeHeader, "bytes="), "-", 2)
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("malformed Range header %q", rangeHeader)
+	}
+	end, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("malformed Range header %q: %w", rangeHeader, err)
+	}
+	return end + 1, nil
+}
@@ -0,0 +1,162 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Decision is the outcome a Classifier returns for a failed attempt, telling a Retrier whether
+// and how to continue.
+type Decision int
+
+const (
+	// Retry means the attempt failed transiently and should be tried again.
+	Retry Decision = iota
+	// Stop means the failure is not retryable by this Retrier, but is not necessarily permanent
+	// (e.g. the caller may want to surface it and let something upstream decide to try again).
+	Stop
+	// StopPermanent means the failure will never succeed on retry (e.g. a non-retryable 4xx, or
+	// a canceled context) and no further attempts should be made.
+	StopPermanent
+)
+
+// Classifier decides what to do with an attempt's error, given the transport-level status code
+// observed (0 if none, e.g. a network error before a response was received).
+type Classifier func(err error, statusCode int) Decision
+
+// Attempt is the outcome of a single try, reported back to a Retrier. StatusCode and RetryAfter
+// let fn hand the Retrier transport-level signal the Classifier and backoff should act on.
+type Attempt struct {
+	Err        error
+	StatusCode int
+	// RetryAfter, if non-zero, is honored instead of the Retrier's own backoff for this attempt
+	// (e.g. a parsed Retry-After response header).
+	RetryAfter time.Duration
+}
+
+// Retrier retries a func() Attempt according to a Classifier, sleeping between attempts with
+// full-jitter exponential backoff: sleep = rand(0, min(Cap, Base*2^attempt)).
+type Retrier struct {
+	// Classify decides whether a given attempt's error is retryable. Defaults to
+	// DefaultHTTPClassifier.
+	Classify Classifier
+	// Base is the initial backoff duration. Defaults to 100ms.
+	Base time.Duration
+	// Cap bounds the backoff duration. Defaults to 1s.
+	Cap time.Duration
+	// MaxAttempts bounds the number of attempts, including the first. Zero means unlimited
+	// (bounded only by Deadline).
+	MaxAttempts int
+	// Deadline, if non-zero, stops retrying once reached.
+	Deadline time.Time
+}
+
+// Do runs fn until it succeeds, Classify says to stop, MaxAttempts is exhausted, the Deadline
+// passes, or ctx is done, returning the last attempt's error (or ctx.Err() when ctx is done
+// while waiting between attempts).
+func (r *Retrier) Do(ctx context.Context, fn func() Attempt) error {
+	classify := r.Classify
+	if classify == nil {
+		classify = DefaultHTTPClassifier
+	}
+	base := r.Base
+	if base <= 0 {
+		base = 100 * time.Millisecond
+	}
+	backoffCap := r.Cap
+	if backoffCap <= 0 {
+		backoffCap = time.Second
+	}
+
+	var lastErr error
+	for attempt := 0; r.MaxAttempts <= 0 || attempt < r.MaxAttempts; attempt++ {
+		a := fn()
+		if a.Err == nil {
+			return nil
+		}
+		lastErr = a.Err
+
+		if classify(a.Err, a.StatusCode) != Retry {
+			return lastErr
+		}
+		if !r.Deadline.IsZero() && !time.Now().Before(r.Deadline) {
+			return lastErr
+		}
+
+		sleep := a.RetryAfter
+		if sleep <= 0 {
+			sleep = fullJitterBackoff(base, backoffCap, attempt)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(sleep):
+		}
+	}
+	return lastErr
+}
+
+// fullJitterBackoff returns a random duration in [0, min(cap, base*2^attempt)].
+func fullJitterBackoff(base, cap time.Duration, attempt int) time.Duration {
+	maxSleep := float64(base) * math.Pow(2, float64(attempt))
+	if maxSleep <= 0 || maxSleep > float64(cap) {
+		maxSleep = float64(cap)
+	}
+	return time.Duration(rand.Int63n(int64(maxSleep) + 1))
+}
+
+// DefaultHTTPClassifier retries on the HTTP status codes that typically indicate a transient
+// failure (408, 425, 429, 500, 502, 503, 504) and on net.Error with Timeout() or Temporary() set,
+// treats context cancellation/deadline-exceeded as permanent, and stops (without retrying) on
+// anything else, such as other 4xx client errors.
+func DefaultHTTPClassifier(err error, statusCode int) Decision {
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return StopPermanent
+		}
+		var netErr net.Error
+		if errors.As(err, &netErr) && (netErr.Timeout() || netErr.Temporary()) { //nolint:staticcheck // Temporary is deprecated but some transports still only signal via it.
+			return Retry
+		}
+	}
+
+	switch statusCode {
+	case http.StatusRequestTimeout, // 408
+		425, // Too Early
+		http.StatusTooManyRequests,     // 429
+		http.StatusInternalServerError, // 500
+		http.StatusBadGateway,          // 502
+		http.StatusServiceUnavailable,  // 503
+		http.StatusGatewayTimeout:      // 504
+		return Retry
+	}
+	return Stop
+}
+
+// ParseRetryAfter parses a Retry-After header value, which per RFC 9110 is either a number of
+// seconds or an HTTP-date, returning the remaining delay and whether a value was found.
+func ParseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if when, err := http.ParseTime(value); err == nil {
+		if d := time.Until(when); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
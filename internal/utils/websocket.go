@@ -0,0 +1,243 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Heartbeat and reconnect-backoff tuning for the proto-over-WebSocket helpers below.
+const (
+	wsPingInterval  = 30 * time.Second
+	wsPongWait      = 60 * time.Second
+	wsReconnectBase = 250 * time.Millisecond
+	wsReconnectCap  = 10 * time.Second
+)
+
+// ProtoWebSocket is the duplex channel pair returned by DialProtoWebSocket. Send carries
+// messages to write to the socket; Recv carries messages read from it. Close Send to shut the
+// connection down cleanly; Recv and Err are both closed once the connection has ended for good
+// (caller shutdown or ctx done; a failed connection is always redialed), with Err reporting why. Err is
+// buffered(1) and sends to it never block, so a caller that only drains Recv still sees Recv
+// close promptly; it may report an earlier transient reconnect failure instead of the final one
+// if both arrive before Err is read.
+type ProtoWebSocket[Send, Recv protoreflect.ProtoMessage] struct {
+	Send chan<- Send
+	Recv <-chan Recv
+	Err  <-chan error
+}
+
+// DialProtoWebSocket dials url as a WebSocket and returns a typed duplex channel pair over it,
+// mirroring the proto-over-HTTP helpers in this package: messages written to Send are
+// protojson-encoded before being sent as text frames, and frames read from the socket are
+// decoded via CustomProtoMessage.UnmarshalJSON (unknown fields are rejected) and delivered on
+// Recv. A background goroutine sends a ping frame every 30s to keep the connection (and any
+// intermediary proxies) alive, and transparently reconnects with jittered exponential backoff if
+// the connection drops before ctx is done or the caller closes Send.
+func DialProtoWebSocket[Send, Recv protoreflect.ProtoMessage](
+	ctx context.Context,
+	url string,
+	headers map[string]string,
+) (*ProtoWebSocket[Send, Recv], error) {
+	h := http.Header{}
+	for k, v := range headers {
+		h.Add(k, v)
+	}
+
+	conn, err := dialProtoWebSocket(ctx, url, h)
+	if err != nil {
+		return nil, fmt.Errorf("DialProtoWebSocket: %w", err)
+	}
+
+	send := make(chan Send)
+	recv := make(chan Recv)
+	errs := make(chan error, 1)
+
+	go runProtoWebSocket(ctx, url, h, conn, send, recv, errs)
+
+	return &ProtoWebSocket[Send, Recv]{Send: send, Recv: recv, Err: errs}, nil
+}
+
+// HandleProtoWebSocket upgrades an incoming HTTP request to a WebSocket and relays protojson
+// frames between it and handler, using the same wire format as DialProtoWebSocket. It blocks
+// until handler returns and then closes the connection; the connection ending first does not by
+// itself end handler (recv is not closed and ctx is not canceled). It returns handler's error
+// if it errored, otherwise the connection's.
+func HandleProtoWebSocket[Send, Recv protoreflect.ProtoMessage](
+	w http.ResponseWriter,
+	r *http.Request,
+	handler func(ctx context.Context, recv <-chan Recv, send chan<- Send) error,
+) error {
+	conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+	if err != nil {
+		return fmt.Errorf("HandleProtoWebSocket: %w", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	send := make(chan Send)
+	recv := make(chan Recv)
+	serveErrs := make(chan error, 1)
+	go func() {
+		serveErrs <- serveProtoWebSocketConn(ctx, conn, send, recv)
+	}()
+
+	handlerErr := handler(ctx, recv, send)
+	cancel()
+
+	if serveErr := <-serveErrs; serveErr != nil && handlerErr == nil {
+		return fmt.Errorf("HandleProtoWebSocket: %w", serveErr)
+	}
+	return handlerErr
+}
+
+func dialProtoWebSocket(ctx context.Context, url string, headers http.Header) (*websocket.Conn, error) {
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, url, headers)
+	return conn, err
+}
+
+// runProtoWebSocket owns a DialProtoWebSocket connection's lifetime: it relays between the
+// channels and the socket via serveProtoWebSocketConn, reconnecting with jittered exponential
+// backoff whenever the connection drops before ctx is done or the caller closes send.
+func runProtoWebSocket[Send, Recv protoreflect.ProtoMessage](
+	ctx context.Context,
+	url string,
+	headers http.Header,
+	conn *websocket.Conn,
+	send chan Send,
+	recv chan Recv,
+	errs chan error,
+) {
+	defer close(recv)
+	defer close(errs)
+
+	attempt := 0
+	for {
+		serveErr := serveProtoWebSocketConn(ctx, conn, send, recv)
+		conn.Close()
+
+		if ctx.Err() != nil {
+			select {
+			case errs <- ctx.Err():
+			default:
+			}
+			return
+		}
+		if serveErr == nil {
+			// The caller closed send: a clean, caller-requested shutdown.
+			return
+		}
+
+		for {
+			sleep := fullJitterBackoff(wsReconnectBase, wsReconnectCap, attempt)
+			attempt++
+
+			select {
+			case <-ctx.Done():
+				select {
+				case errs <- ctx.Err():
+				default:
+				}
+				return
+			case <-time.After(sleep):
+			}
+
+			var dialErr error
+			conn, dialErr = dialProtoWebSocket(ctx, url, headers)
+			if dialErr == nil {
+				break
+			}
+			select {
+			case errs <- fmt.Errorf("reconnect: %w", dialErr):
+			default:
+			}
+		}
+	}
+}
+
+// serveProtoWebSocketConn relays between conn and the channels for as long as the connection is
+// alive: it answers the heartbeat, encodes outgoing messages, and decodes incoming ones. It
+// returns nil when ctx is done or the caller closes send (both clean shutdowns), and a non-nil
+// error when the connection itself failed (read/write error, a frame that fails to decode, or the
+// peer closing the connection, even with a normal close frame).
+func serveProtoWebSocketConn[Send, Recv protoreflect.ProtoMessage](
+	ctx context.Context,
+	conn *websocket.Conn,
+	send chan Send,
+	recv chan Recv,
+) error {
+	_ = conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	readMsgs := make(chan Recv)
+	readErrs := make(chan error, 1)
+	go func() {
+		defer close(readMsgs)
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				readErrs <- err
+				return
+			}
+			var msg CustomProtoMessage[Recv]
+			if err := msg.UnmarshalJSON(data); err != nil {
+				readErrs <- fmt.Errorf("decode frame: %w", err)
+				return
+			}
+			select {
+			case readMsgs <- msg.Msg:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(wsPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			_ = conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+				time.Now().Add(time.Second))
+			return nil
+
+		case m, ok := <-send:
+			if !ok {
+				return nil
+			}
+			b, err := protojson.Marshal(m)
+			if err != nil {
+				return fmt.Errorf("encode frame: %w", err)
+			}
+			if err := conn.WriteMessage(websocket.TextMessage, b); err != nil {
+				return err
+			}
+
+		case msg, ok := <-readMsgs:
+			if !ok {
+				return <-readErrs
+			}
+			select {
+			case recv <- msg:
+			case <-ctx.Done():
+				return nil
+			}
+
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(5*time.Second)); err != nil {
+				return err
+			}
+		}
+	}
+}
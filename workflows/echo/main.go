@@ -28,7 +28,9 @@ func main() {
 		http.MethodPost,
 		&req,
 		nil,
-		&resp)
+		&resp,
+		nil,
+		nil)
 	if err != nil {
 		panic(err)
 	}